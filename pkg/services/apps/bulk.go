@@ -0,0 +1,192 @@
+package apps
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rollbackTimeout bounds the whole cleanup pass made by rollbackCreated/rollbackUpdated: all of a
+// pass's cleanup calls share one context with this timeout. It runs on a context decoupled from the
+// caller's, so a batch that failed because the caller's context was already cancelled or expired
+// doesn't take its cleanup pass down with it.
+const rollbackTimeout = 30 * time.Second
+
+// BulkStatus describes what happened to a single app as part of a bulk operation.
+type BulkStatus string
+
+const (
+	// BulkStatusSuccess means the app was created/updated as requested.
+	BulkStatusSuccess BulkStatus = "success"
+	// BulkStatusFailed means the app's own create/update call failed.
+	BulkStatusFailed BulkStatus = "failed"
+	// BulkStatusRolledBack means the app was undone because an app in the same
+	// batch failed and BulkOptions.RollbackOnError was set. That includes the
+	// failing app itself when it had already been created (or, for an update,
+	// had its pre-update state captured) before it failed.
+	BulkStatusRolledBack BulkStatus = "rolled_back"
+	// BulkStatusRollbackFailed means an app in the same batch failed (possibly
+	// this one, after it was created or partially applied),
+	// BulkOptions.RollbackOnError was set, and the attempt to undo this app
+	// itself failed — it's left in
+	// whatever state the server has it in, not the state Status would
+	// otherwise imply.
+	BulkStatusRollbackFailed BulkStatus = "rollback_failed"
+)
+
+// BulkOptions configures BulkCreate and BulkUpdate.
+type BulkOptions struct {
+	// RollbackOnError undoes every app already created/updated in the batch
+	// as soon as any app in the batch fails, giving the caller all-or-nothing
+	// semantics instead of a partially applied batch.
+	RollbackOnError bool
+}
+
+// BulkResult reports the outcome for a single app processed by BulkCreate or
+// BulkUpdate.
+type BulkResult struct {
+	App    *App
+	Status BulkStatus
+	Err    error
+}
+
+// BulkCreate creates many apps concurrently (respecting svc.Concurrency). If
+// options.RollbackOnError is set and any app fails to create, every app this
+// batch actually created server-side is destroyed and reported as
+// BulkStatusRolledBack, including ones whose own CreateContext call returned
+// an error after the app was already created (e.g. a rule save failure).
+// An app is destroyed only if its result carries the app's ID: when a rule
+// save fails and the GetOne recovery read after it fails too, CreateContext
+// returns no app, so that app is left in place server-side.
+func (svc *V2Service) BulkCreate(ctx context.Context, apps []*App, options BulkOptions) ([]BulkResult, error) {
+	results := make([]BulkResult, len(apps))
+
+	err := svc.runPooled(ctx, len(apps), func(ctx context.Context, i int) error {
+		// newApp is kept even on error: CreateContext falls back to a GetOne
+		// read when saving an app's rules fails partway through, so newApp may
+		// already carry the ID of an app that exists server-side despite err
+		// being non-nil.
+		newApp, err := svc.CreateContext(ctx, apps[i])
+		if err != nil {
+			results[i] = BulkResult{App: newApp, Status: BulkStatusFailed, Err: err}
+			return err
+		}
+		results[i] = BulkResult{App: newApp, Status: BulkStatusSuccess}
+		return nil
+	})
+
+	if err != nil && options.RollbackOnError {
+		svc.rollbackCreated(ctx, results)
+	}
+
+	return results, err
+}
+
+// rollbackCreated runs on a context decoupled from ctx (see rollbackTimeout): ctx is the same
+// context BulkCreate's batch just failed under, which may itself be why it failed (cancellation,
+// deadline), and reusing it here would make every cleanup call fail the same way.
+func (svc *V2Service) rollbackCreated(ctx context.Context, results []BulkResult) {
+	rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	for i := range results {
+		// Roll back anything that was actually created server-side, whether or
+		// not its own CreateContext call ultimately returned an error.
+		if results[i].Status == BulkStatusRolledBack || results[i].App == nil || results[i].App.ID == nil {
+			continue
+		}
+		if err := svc.DestroyContext(rollbackCtx, *results[i].App.ID); err != nil {
+			results[i].Status = BulkStatusRollbackFailed
+			results[i].Err = err
+			continue
+		}
+		results[i].Status = BulkStatusRolledBack
+	}
+}
+
+// BulkUpdate updates many apps concurrently (respecting svc.Concurrency),
+// capturing each app's pre-update state via GetOneContext before mutating it.
+// If options.RollbackOnError is set and any app fails to update, every app
+// this batch actually updated server-side is restored to its captured
+// pre-update state and reported as BulkStatusRolledBack, including ones whose
+// own UpdateContext call returned an error after partially applying (e.g. a
+// rule/parameter prune failure).
+func (svc *V2Service) BulkUpdate(ctx context.Context, apps map[int32]*App, options BulkOptions) (map[int32]BulkResult, error) {
+	ids := make([]int32, 0, len(apps))
+	for id := range apps {
+		ids = append(ids, id)
+	}
+
+	var (
+		mu       sync.Mutex
+		results  = make(map[int32]BulkResult, len(ids))
+		previous = make(map[int32]*App, len(ids))
+	)
+
+	err := svc.runPooled(ctx, len(ids), func(ctx context.Context, i int) error {
+		id := ids[i]
+
+		priorState, err := svc.GetOneContext(ctx, id)
+		if err != nil {
+			mu.Lock()
+			results[id] = BulkResult{App: apps[id], Status: BulkStatusFailed, Err: err}
+			mu.Unlock()
+			return err
+		}
+
+		// previous is recorded before the mutating call, not after it succeeds:
+		// UpdateContext falls back to a GetOne read when saving rules/parameters
+		// fails partway through, so the app can be partially updated server-side
+		// even when it returns a non-nil error.
+		mu.Lock()
+		previous[id] = priorState
+		mu.Unlock()
+
+		updatedApp, err := svc.UpdateContext(ctx, id, apps[id])
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			results[id] = BulkResult{App: updatedApp, Status: BulkStatusFailed, Err: err}
+			return err
+		}
+		results[id] = BulkResult{App: updatedApp, Status: BulkStatusSuccess}
+		return nil
+	})
+
+	if err != nil && options.RollbackOnError {
+		svc.rollbackUpdated(ctx, previous, results)
+	}
+
+	return results, err
+}
+
+// rollbackUpdated runs on a context decoupled from ctx, for the same reason rollbackCreated does:
+// see rollbackTimeout.
+func (svc *V2Service) rollbackUpdated(ctx context.Context, previous map[int32]*App, results map[int32]BulkResult) {
+	rollbackCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rollbackTimeout)
+	defer cancel()
+
+	for id, result := range results {
+		// Restore anything that was actually updated server-side, whether or
+		// not its own UpdateContext call ultimately returned an error.
+		if result.Status == BulkStatusRolledBack {
+			continue
+		}
+		priorState, ok := previous[id]
+		if !ok {
+			continue
+		}
+
+		restored, err := svc.UpdateContext(rollbackCtx, id, priorState)
+		if err != nil {
+			result.Status = BulkStatusRollbackFailed
+			result.Err = err
+			results[id] = result
+			continue
+		}
+		result.App = restored
+		result.Status = BulkStatusRolledBack
+		results[id] = result
+	}
+}
@@ -0,0 +1,151 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
+)
+
+// fakeRepo is a minimal services.Repository that records Destroy/Update
+// calls so rollback behavior can be asserted without a real HTTP transport.
+type fakeRepo struct {
+	destroyed  []string
+	updated    []string
+	destroyErr error
+}
+
+// Read serves the reads UpdateContext makes after its update call: an empty
+// list for a rules lookup, otherwise the app whose ID ends the URL.
+func (f *fakeRepo) Read(req olhttp.OLHTTPRequest) (*olhttp.Response, error) {
+	if strings.HasSuffix(req.URL, "/rules") {
+		return &olhttp.Response{Body: []byte(`[]`)}, nil
+	}
+	return &olhttp.Response{Body: appBodyFor(req.URL)}, nil
+}
+
+func (f *fakeRepo) Create(olhttp.OLHTTPRequest) (*olhttp.Response, error) { return nil, nil }
+
+func (f *fakeRepo) Update(req olhttp.OLHTTPRequest) (*olhttp.Response, error) {
+	f.updated = append(f.updated, req.URL)
+	return &olhttp.Response{Body: appBodyFor(req.URL)}, nil
+}
+
+// appBodyFor returns an app body whose id is the last path segment of url.
+func appBodyFor(url string) []byte {
+	return []byte(`{"id":` + url[strings.LastIndex(url, "/")+1:] + `}`)
+}
+
+func (f *fakeRepo) Destroy(req olhttp.OLHTTPRequest) (*olhttp.Response, error) {
+	f.destroyed = append(f.destroyed, req.URL)
+	if f.destroyErr != nil {
+		return nil, f.destroyErr
+	}
+	return nil, nil
+}
+
+func TestRollbackCreatedDestroysAppsWithIDRegardlessOfStatus(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := &V2Service{Endpoint: "https://example.com/api/2/apps", Repository: repo}
+
+	created := int32(1)
+	partiallyCreated := int32(2)
+	results := []BulkResult{
+		{App: &App{ID: &created}, Status: BulkStatusSuccess},
+		// CreateContext returned an error, but the app exists server-side.
+		{App: &App{ID: &partiallyCreated}, Status: BulkStatusFailed},
+		// CreateContext's own HTTP call failed before anything was created.
+		{App: &App{}, Status: BulkStatusFailed},
+	}
+
+	svc.rollbackCreated(context.Background(), results)
+
+	if len(repo.destroyed) != 2 {
+		t.Fatalf("destroyed %d apps, want 2 (one success, one partially-created failure)", len(repo.destroyed))
+	}
+	if results[0].Status != BulkStatusRolledBack {
+		t.Fatalf("results[0].Status = %v, want %v", results[0].Status, BulkStatusRolledBack)
+	}
+	if results[1].Status != BulkStatusRolledBack {
+		t.Fatalf("results[1].Status = %v, want %v (it was created despite the error)", results[1].Status, BulkStatusRolledBack)
+	}
+	if results[2].Status != BulkStatusFailed {
+		t.Fatalf("results[2].Status = %v, want %v (nothing was ever created for it)", results[2].Status, BulkStatusFailed)
+	}
+}
+
+func TestRollbackUpdatedRestoresAppsMutatedDespiteError(t *testing.T) {
+	repo := &fakeRepo{}
+	// New wires up the Rules/Parameters services and Logger that UpdateContext uses.
+	svc := New(repo, "https://example.com")
+
+	id1, id2, id3 := int32(1), int32(2), int32(3)
+	previous := map[int32]*App{
+		id1: {ID: &id1},
+		id2: {ID: &id2},
+		// id3 never reached UpdateContext (GetOneContext itself failed), so it
+		// has no captured prior state and must not be touched.
+	}
+	results := map[int32]BulkResult{
+		id1: {App: &App{ID: &id1}, Status: BulkStatusSuccess},
+		id2: {App: &App{ID: &id2}, Status: BulkStatusFailed},
+		id3: {App: nil, Status: BulkStatusFailed},
+	}
+
+	svc.rollbackUpdated(context.Background(), previous, results)
+
+	if len(repo.updated) != 2 {
+		t.Fatalf("updated %d apps, want 2 (restoring id1 and id2)", len(repo.updated))
+	}
+	if results[id1].Status != BulkStatusRolledBack || results[id2].Status != BulkStatusRolledBack {
+		t.Fatal("both apps with captured prior state should be reported as rolled back")
+	}
+	if results[id3].Status != BulkStatusFailed {
+		t.Fatal("the app with no captured prior state should be left alone")
+	}
+}
+
+func TestRollbackCreatedMarksStatusRollbackFailedWhenDestroyErrors(t *testing.T) {
+	repo := &fakeRepo{destroyErr: errors.New("destroy failed")}
+	svc := &V2Service{Endpoint: "https://example.com/api/2/apps", Repository: repo}
+
+	created := int32(1)
+	results := []BulkResult{
+		{App: &App{ID: &created}, Status: BulkStatusSuccess},
+	}
+
+	svc.rollbackCreated(context.Background(), results)
+
+	if results[0].Status != BulkStatusRollbackFailed {
+		t.Fatalf("results[0].Status = %v, want %v since the app was never actually rolled back", results[0].Status, BulkStatusRollbackFailed)
+	}
+	if results[0].Err == nil {
+		t.Fatal("results[0].Err should carry the destroy failure")
+	}
+}
+
+func TestRollbackCreatedUsesContextDecoupledFromCaller(t *testing.T) {
+	repo := &fakeRepo{}
+	svc := &V2Service{Endpoint: "https://example.com/api/2/apps", Repository: repo}
+
+	// Simulate BulkCreate's ctx having already been cancelled by the time the
+	// batch failed: the rollback pass must still go through.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	created := int32(1)
+	results := []BulkResult{
+		{App: &App{ID: &created}, Status: BulkStatusSuccess},
+	}
+
+	svc.rollbackCreated(ctx, results)
+
+	if len(repo.destroyed) != 1 {
+		t.Fatalf("destroyed %d apps, want 1 even though the caller's context was cancelled", len(repo.destroyed))
+	}
+	if results[0].Status != BulkStatusRolledBack {
+		t.Fatalf("results[0].Status = %v, want %v", results[0].Status, BulkStatusRolledBack)
+	}
+}
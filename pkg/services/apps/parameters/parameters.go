@@ -0,0 +1,131 @@
+// Package parameters exposes the app parameters nested under the OneLogin
+// Apps v2 API. It was split out of apps.V2Service because parameters have
+// their own list/upsert/delete lifecycle (used for reconciling an app's
+// parameter set on update) that callers may also want to drive directly,
+// without fetching or saving the rest of the app.
+package parameters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
+)
+
+// Parameter is a single app parameter, e.g. a mapping from a user field to a
+// value passed to the app at login. ID is modeled explicitly since both this
+// package and its callers need it; every other attribute round-trips through
+// Attributes so this package isn't coupled to the full set of fields a
+// parameter can carry.
+type Parameter struct {
+	ID         *int32
+	Attributes map[string]interface{}
+}
+
+// MarshalJSON flattens Attributes back out alongside ID so a Parameter
+// serializes the same way it was received.
+func (p Parameter) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(p.Attributes)+1)
+	for k, v := range p.Attributes {
+		out[k] = v
+	}
+	if p.ID != nil {
+		out["id"] = *p.ID
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON pulls id out into ID and keeps everything else in Attributes.
+func (p *Parameter) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if id, ok := raw["id"].(float64); ok {
+		p.ID = oltypes.Int32(int32(id))
+	}
+	delete(raw, "id")
+	p.Attributes = raw
+	return nil
+}
+
+// ParametersService holds the information needed to interface with a
+// repository for the parameters nested under an app.
+type ParametersService struct {
+	Endpoint   string
+	Repository services.Repository
+}
+
+// New creates a ParametersService for the apps rooted at host, using repo to
+// make the underlying calls.
+func New(repo services.Repository, host string) *ParametersService {
+	return &ParametersService{
+		Endpoint:   fmt.Sprintf("%s/api/2/apps", host),
+		Repository: repo,
+	}
+}
+
+// List retrieves every parameter belonging to the app with the given ID.
+func (svc *ParametersService) List(ctx context.Context, appID int32) ([]Parameter, error) {
+	resp, err := svc.Repository.Read(olhttp.OLHTTPRequest{
+		URL:        fmt.Sprintf("%s/%d/parameters", svc.Endpoint, appID),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		AuthMethod: "bearer",
+		Context:    ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var params []Parameter
+	json.Unmarshal(resp.Body, &params)
+	return params, nil
+}
+
+// Upsert creates param if it has no ID, or updates it in place otherwise, and
+// returns it with its ID populated.
+func (svc *ParametersService) Upsert(ctx context.Context, appID int32, param Parameter) (Parameter, error) {
+	var (
+		resp *olhttp.Response
+		err  error
+	)
+	if param.ID != nil {
+		resp, err = svc.Repository.Update(olhttp.OLHTTPRequest{
+			URL:        fmt.Sprintf("%s/%d/parameters/%d", svc.Endpoint, appID, *param.ID),
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			AuthMethod: "bearer",
+			Payload:    param,
+			Context:    ctx,
+		})
+	} else {
+		resp, err = svc.Repository.Create(olhttp.OLHTTPRequest{
+			URL:        fmt.Sprintf("%s/%d/parameters", svc.Endpoint, appID),
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			AuthMethod: "bearer",
+			Payload:    param,
+			Context:    ctx,
+		})
+	}
+	if err != nil {
+		return param, err
+	}
+
+	var paramID map[string]int
+	json.Unmarshal(resp.Body, &paramID)
+	param.ID = oltypes.Int32(int32(paramID["id"]))
+	return param, nil
+}
+
+// Delete removes the parameter with the given ID from the app with the given ID.
+func (svc *ParametersService) Delete(ctx context.Context, appID, paramID int32) error {
+	_, err := svc.Repository.Destroy(olhttp.OLHTTPRequest{
+		URL:        fmt.Sprintf("%s/%d/parameters/%d", svc.Endpoint, appID, paramID),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		AuthMethod: "bearer",
+		Context:    ctx,
+	})
+	return err
+}
@@ -0,0 +1,48 @@
+package parameters
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
+)
+
+func TestParameterMarshalUnmarshalRoundTrip(t *testing.T) {
+	param := Parameter{
+		ID:         oltypes.Int32(7),
+		Attributes: map[string]interface{}{"param_key_name": "email", "user_attribute_mappings": "email"},
+	}
+
+	data, err := json.Marshal(param)
+	if err != nil {
+		t.Fatalf("Marshal returned %v", err)
+	}
+
+	var got Parameter
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal returned %v", err)
+	}
+
+	if got.ID == nil || *got.ID != 7 {
+		t.Fatalf("ID = %v, want 7", got.ID)
+	}
+	if got.Attributes["param_key_name"] != "email" {
+		t.Fatalf("Attributes[param_key_name] = %v, want %q", got.Attributes["param_key_name"], "email")
+	}
+	if _, ok := got.Attributes["id"]; ok {
+		t.Fatal("Attributes should not contain id once it has been pulled out into ID")
+	}
+}
+
+func TestParameterUnmarshalWithoutID(t *testing.T) {
+	var param Parameter
+	if err := json.Unmarshal([]byte(`{"param_key_name":"no id yet"}`), &param); err != nil {
+		t.Fatalf("Unmarshal returned %v", err)
+	}
+	if param.ID != nil {
+		t.Fatalf("ID = %v, want nil since the payload had none", param.ID)
+	}
+	if param.Attributes["param_key_name"] != "no id yet" {
+		t.Fatalf("Attributes[param_key_name] = %v, want %q", param.Attributes["param_key_name"], "no id yet")
+	}
+}
@@ -0,0 +1,67 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRunPooledCallsEveryIndex(t *testing.T) {
+	svc := &V2Service{Concurrency: 2}
+
+	var seen int32
+	err := svc.runPooled(context.Background(), 5, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&seen, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("runPooled returned %v, want nil", err)
+	}
+	if seen != 5 {
+		t.Fatalf("fn called %d times, want 5", seen)
+	}
+}
+
+func TestRunPooledAggregatesErrors(t *testing.T) {
+	svc := &V2Service{Concurrency: 3}
+	errA := errors.New("a failed")
+	errB := errors.New("b failed")
+
+	err := svc.runPooled(context.Background(), 4, func(ctx context.Context, i int) error {
+		switch i {
+		case 0:
+			return errA
+		case 1:
+			return errB
+		default:
+			return nil
+		}
+	})
+	if err == nil {
+		t.Fatal("runPooled returned nil, want an aggregated error since two of the four calls failed")
+	}
+}
+
+func TestRunPooledStopsDispatchingOnCancelledContext(t *testing.T) {
+	svc := &V2Service{Concurrency: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := svc.runPooled(ctx, 5, func(ctx context.Context, i int) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatal("runPooled returned nil, want an error since ctx was already cancelled before dispatch")
+	}
+}
+
+func TestRunPooledNoWork(t *testing.T) {
+	svc := &V2Service{Concurrency: 2}
+	if err := svc.runPooled(context.Background(), 0, func(ctx context.Context, i int) error {
+		t.Fatal("fn should not be called when n is 0")
+		return nil
+	}); err != nil {
+		t.Fatalf("runPooled returned %v, want nil", err)
+	}
+}
@@ -0,0 +1,10 @@
+package apps
+
+// AppsQuery holds the criteria accepted by Query, QueryContext, and
+// QueryStream.
+type AppsQuery struct {
+	// MaxResults caps the total number of apps Query/QueryContext/QueryStream
+	// return, across however many pages it takes the API to deliver them.
+	// Zero means no cap.
+	MaxResults int
+}
@@ -0,0 +1,140 @@
+package apps
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
+)
+
+// pagedRepo serves a fixed sequence of app-list pages keyed by URL, plus an
+// empty rules list for every per-app rules lookup, so QueryContext/QueryStream
+// can be exercised without a real HTTP transport.
+type pagedRepo struct {
+	pages map[string]*olhttp.Response
+}
+
+func (r *pagedRepo) Read(req olhttp.OLHTTPRequest) (*olhttp.Response, error) {
+	if page, ok := r.pages[req.URL]; ok {
+		return page, nil
+	}
+	// Anything not in pages is a per-app rules lookup.
+	return &olhttp.Response{Body: []byte(`[]`)}, nil
+}
+
+func (r *pagedRepo) Create(olhttp.OLHTTPRequest) (*olhttp.Response, error) { return nil, nil }
+func (r *pagedRepo) Update(olhttp.OLHTTPRequest) (*olhttp.Response, error) { return nil, nil }
+func (r *pagedRepo) Destroy(olhttp.OLHTTPRequest) (*olhttp.Response, error) { return nil, nil }
+
+const endpoint = "https://example.com/api/2/apps"
+
+func TestDecodeAppsPageEnvelopeNextLink(t *testing.T) {
+	body := []byte(`{"data":[{"id":1}],"pagination":{"next_link":"https://example.com/api/2/apps?cursor=2"}}`)
+
+	apps, nextLink, err := decodeAppsPage(body, http.Header{})
+	if err != nil {
+		t.Fatalf("decodeAppsPage returned %v", err)
+	}
+	if len(apps) != 1 || apps[0].ID == nil || *apps[0].ID != 1 {
+		t.Fatalf("apps = %+v, want one app with ID 1", apps)
+	}
+	if nextLink != "https://example.com/api/2/apps?cursor=2" {
+		t.Fatalf("nextLink = %q, want the envelope's next_link", nextLink)
+	}
+}
+
+func TestDecodeAppsPageLinkHeaderFallback(t *testing.T) {
+	body := []byte(`[{"id":1}]`)
+	header := http.Header{"Link": []string{`<https://example.com/api/2/apps?cursor=2>; rel="next"`}}
+
+	apps, nextLink, err := decodeAppsPage(body, header)
+	if err != nil {
+		t.Fatalf("decodeAppsPage returned %v", err)
+	}
+	if len(apps) != 1 {
+		t.Fatalf("apps = %+v, want one app", apps)
+	}
+	if nextLink != "https://example.com/api/2/apps?cursor=2" {
+		t.Fatalf("nextLink = %q, want the Link header's rel=\"next\" URL", nextLink)
+	}
+}
+
+func TestDecodeAppsPageNoNextPage(t *testing.T) {
+	apps, nextLink, err := decodeAppsPage([]byte(`[{"id":1}]`), http.Header{})
+	if err != nil {
+		t.Fatalf("decodeAppsPage returned %v", err)
+	}
+	if len(apps) != 1 || nextLink != "" {
+		t.Fatalf("apps = %+v, nextLink = %q, want one app and no next link", apps, nextLink)
+	}
+}
+
+func TestQueryContextFollowsNextLinkAndStops(t *testing.T) {
+	page2URL := endpoint + "?cursor=2"
+	repo := &pagedRepo{pages: map[string]*olhttp.Response{
+		endpoint: {Body: []byte(`{"data":[{"id":1},{"id":2}],"pagination":{"next_link":"` + page2URL + `"}}`)},
+		page2URL: {Body: []byte(`{"data":[{"id":3}],"pagination":{"next_link":""}}`)},
+	}}
+	svc := &V2Service{Endpoint: endpoint, Repository: repo}
+
+	apps, err := svc.QueryContext(context.Background(), &AppsQuery{})
+	if err != nil {
+		t.Fatalf("QueryContext returned %v", err)
+	}
+	if len(apps) != 3 {
+		t.Fatalf("got %d apps, want 3 across both pages", len(apps))
+	}
+}
+
+func TestQueryContextHonorsMaxResults(t *testing.T) {
+	page2URL := endpoint + "?cursor=2"
+	repo := &pagedRepo{pages: map[string]*olhttp.Response{
+		endpoint: {Body: []byte(`{"data":[{"id":1},{"id":2}],"pagination":{"next_link":"` + page2URL + `"}}`)},
+		page2URL: {Body: []byte(`{"data":[{"id":3}],"pagination":{"next_link":""}}`)},
+	}}
+	svc := &V2Service{Endpoint: endpoint, Repository: repo}
+
+	apps, err := svc.QueryContext(context.Background(), &AppsQuery{MaxResults: 1})
+	if err != nil {
+		t.Fatalf("QueryContext returned %v", err)
+	}
+	if len(apps) != 1 {
+		t.Fatalf("got %d apps, want 1 since MaxResults capped it", len(apps))
+	}
+}
+
+func TestQueryContextNilQueryDoesNotPanic(t *testing.T) {
+	repo := &pagedRepo{pages: map[string]*olhttp.Response{
+		endpoint: {Body: []byte(`[{"id":1}]`)},
+	}}
+	svc := &V2Service{Endpoint: endpoint, Repository: repo}
+
+	apps, err := svc.QueryContext(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("QueryContext(nil) returned %v, want nil error", err)
+	}
+	if len(apps) != 1 {
+		t.Fatalf("got %d apps, want 1", len(apps))
+	}
+}
+
+func TestQueryStreamNilQueryDoesNotPanic(t *testing.T) {
+	repo := &pagedRepo{pages: map[string]*olhttp.Response{
+		endpoint: {Body: []byte(`[{"id":1}]`)},
+	}}
+	svc := &V2Service{Endpoint: endpoint, Repository: repo}
+
+	appsCh, errCh := svc.QueryStream(context.Background(), nil)
+
+	var got []App
+	for app := range appsCh {
+		got = append(got, app)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("QueryStream(nil) sent error %v, want nil", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("got %d apps, want 1", len(got))
+	}
+}
@@ -0,0 +1,103 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy configures how per-rule/per-parameter HTTP calls are retried
+// against OneLogin's rate limits and transient failures.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of tries, including the first. Values
+	// <= 1 disable retrying.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; each subsequent retry
+	// doubles it, capped at MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff delay.
+	MaxDelay time.Duration
+	// Jitter adds up to this much additional random delay to each retry, so
+	// concurrent callers don't retry in lockstep.
+	Jitter time.Duration
+	// RetryableStatusCodes lists the HTTP status codes that are safe to retry.
+	RetryableStatusCodes []int
+}
+
+// defaultRetryPolicy is used when a V2Service is constructed without an
+// explicit RetryPolicy in its V2ServiceOptions.
+var defaultRetryPolicy = RetryPolicy{
+	MaxAttempts:          3,
+	BaseDelay:            500 * time.Millisecond,
+	MaxDelay:             10 * time.Second,
+	Jitter:               250 * time.Millisecond,
+	RetryableStatusCodes: []int{429, 500, 502, 503, 504},
+}
+
+// httpStatusError is satisfied by olhttp.HTTPError, giving the retry loop
+// access to the failed response's status code and any Retry-After hint
+// without this package depending on olhttp's concrete error type.
+type httpStatusError interface {
+	error
+	StatusCode() int
+	RetryAfter() time.Duration
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	var statusErr httpStatusError
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if statusErr.StatusCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var statusErr httpStatusError
+	if errors.As(err, &statusErr) {
+		if retryAfter := statusErr.RetryAfter(); retryAfter > 0 {
+			return retryAfter
+		}
+	}
+
+	delay := p.BaseDelay << uint(attempt)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		delay += time.Duration(rand.Int63n(int64(p.Jitter)))
+	}
+	return delay
+}
+
+// withRetry runs fn, retrying according to policy whenever fn returns a
+// retryable httpStatusError. It stops early, returning ctx.Err(), if ctx is
+// cancelled while waiting out a backoff delay.
+func withRetry(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt == maxAttempts-1 || !policy.retryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(policy.delay(attempt, err)):
+		}
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package apps
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type fakeStatusError struct {
+	statusCode int
+	retryAfter time.Duration
+}
+
+func (e fakeStatusError) Error() string            { return "fake status error" }
+func (e fakeStatusError) StatusCode() int           { return e.statusCode }
+func (e fakeStatusError) RetryAfter() time.Duration { return e.retryAfter }
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	policy := RetryPolicy{RetryableStatusCodes: []int{429, 503}}
+
+	if policy.retryable(errors.New("boom")) {
+		t.Fatal("a plain error should never be retryable")
+	}
+	if !policy.retryable(fakeStatusError{statusCode: 429}) {
+		t.Fatal("429 is in RetryableStatusCodes and should be retryable")
+	}
+	if policy.retryable(fakeStatusError{statusCode: 404}) {
+		t.Fatal("404 is not in RetryableStatusCodes and should not be retryable")
+	}
+}
+
+func TestRetryPolicyDelayHonorsRetryAfter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, MaxDelay: time.Minute}
+	err := fakeStatusError{statusCode: 429, retryAfter: 5 * time.Second}
+
+	if got := policy.delay(0, err); got != 5*time.Second {
+		t.Fatalf("delay() = %v, want the error's RetryAfter (5s)", got)
+	}
+}
+
+func TestWithRetrySucceedsAfterTransientFailures(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:          3,
+		BaseDelay:            time.Millisecond,
+		MaxDelay:             time.Millisecond,
+		RetryableStatusCodes: []int{429},
+	}
+
+	attempts := 0
+	err := withRetry(context.Background(), policy, func() error {
+		attempts++
+		if attempts < 3 {
+			return fakeStatusError{statusCode: 429}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("withRetry returned %v, want nil after succeeding on the final attempt", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("fn called %d times, want 3", attempts)
+	}
+}
+
+func TestWithRetryStopsOnNonRetryableError(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, RetryableStatusCodes: []int{429}}
+
+	attempts := 0
+	wantErr := errors.New("not retryable")
+	err := withRetry(context.Background(), policy, func() error {
+		attempts++
+		return wantErr
+	})
+	if err != wantErr {
+		t.Fatalf("withRetry returned %v, want %v", err, wantErr)
+	}
+	if attempts != 1 {
+		t.Fatalf("fn called %d times, want 1 since the error isn't retryable", attempts)
+	}
+}
+
+func TestWithRetryStopsWhenContextCancelled(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:          5,
+		BaseDelay:            time.Minute,
+		MaxDelay:             time.Minute,
+		RetryableStatusCodes: []int{429},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	attempts := 0
+	err := withRetry(ctx, policy, func() error {
+		attempts++
+		return fakeStatusError{statusCode: 429}
+	})
+	if err != context.Canceled {
+		t.Fatalf("withRetry returned %v, want context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("fn called %d times, want 1 since the backoff wait should have been cut short", attempts)
+	}
+}
@@ -0,0 +1,131 @@
+// Package rules exposes the app rules nested under the OneLogin Apps v2 API.
+// It was split out of apps.V2Service because rules have their own
+// list/upsert/delete lifecycle (used for reconciling an app's rule set on
+// update) that callers may also want to drive directly, without fetching or
+// saving the rest of the app.
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
+)
+
+// Rule is a single app rule, e.g. a condition/action pair that assigns a
+// role based on group membership. ID is modeled explicitly since both this
+// package and its callers need it; every other attribute round-trips through
+// Attributes so this package isn't coupled to the full set of fields a rule
+// can carry.
+type Rule struct {
+	ID         *int32
+	Attributes map[string]interface{}
+}
+
+// MarshalJSON flattens Attributes back out alongside ID so a Rule serializes
+// the same way it was received.
+func (r Rule) MarshalJSON() ([]byte, error) {
+	out := make(map[string]interface{}, len(r.Attributes)+1)
+	for k, v := range r.Attributes {
+		out[k] = v
+	}
+	if r.ID != nil {
+		out["id"] = *r.ID
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON pulls id out into ID and keeps everything else in Attributes.
+func (r *Rule) UnmarshalJSON(data []byte) error {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if id, ok := raw["id"].(float64); ok {
+		r.ID = oltypes.Int32(int32(id))
+	}
+	delete(raw, "id")
+	r.Attributes = raw
+	return nil
+}
+
+// RulesService holds the information needed to interface with a repository
+// for the rules nested under an app.
+type RulesService struct {
+	Endpoint   string
+	Repository services.Repository
+}
+
+// New creates a RulesService for the apps rooted at host, using repo to make
+// the underlying calls.
+func New(repo services.Repository, host string) *RulesService {
+	return &RulesService{
+		Endpoint:   fmt.Sprintf("%s/api/2/apps", host),
+		Repository: repo,
+	}
+}
+
+// List retrieves every rule belonging to the app with the given ID.
+func (svc *RulesService) List(ctx context.Context, appID int32) ([]Rule, error) {
+	resp, err := svc.Repository.Read(olhttp.OLHTTPRequest{
+		URL:        fmt.Sprintf("%s/%d/rules", svc.Endpoint, appID),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		AuthMethod: "bearer",
+		Context:    ctx,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []Rule
+	json.Unmarshal(resp.Body, &rules)
+	return rules, nil
+}
+
+// Upsert creates rule if it has no ID, or updates it in place otherwise, and
+// returns it with its ID populated.
+func (svc *RulesService) Upsert(ctx context.Context, appID int32, rule Rule) (Rule, error) {
+	var (
+		resp *olhttp.Response
+		err  error
+	)
+	if rule.ID != nil {
+		resp, err = svc.Repository.Update(olhttp.OLHTTPRequest{
+			URL:        fmt.Sprintf("%s/%d/rules/%d", svc.Endpoint, appID, *rule.ID),
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			AuthMethod: "bearer",
+			Payload:    rule,
+			Context:    ctx,
+		})
+	} else {
+		resp, err = svc.Repository.Create(olhttp.OLHTTPRequest{
+			URL:        fmt.Sprintf("%s/%d/rules", svc.Endpoint, appID),
+			Headers:    map[string]string{"Content-Type": "application/json"},
+			AuthMethod: "bearer",
+			Payload:    rule,
+			Context:    ctx,
+		})
+	}
+	if err != nil {
+		return rule, err
+	}
+
+	var ruleID map[string]int
+	json.Unmarshal(resp.Body, &ruleID)
+	rule.ID = oltypes.Int32(int32(ruleID["id"]))
+	return rule, nil
+}
+
+// Delete removes the rule with the given ID from the app with the given ID.
+func (svc *RulesService) Delete(ctx context.Context, appID, ruleID int32) error {
+	_, err := svc.Repository.Destroy(olhttp.OLHTTPRequest{
+		URL:        fmt.Sprintf("%s/%d/rules/%d", svc.Endpoint, appID, ruleID),
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		AuthMethod: "bearer",
+		Context:    ctx,
+	})
+	return err
+}
@@ -0,0 +1,48 @@
+package rules
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
+)
+
+func TestRuleMarshalUnmarshalRoundTrip(t *testing.T) {
+	rule := Rule{
+		ID:         oltypes.Int32(42),
+		Attributes: map[string]interface{}{"name": "Assign Admins", "enabled": true},
+	}
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		t.Fatalf("Marshal returned %v", err)
+	}
+
+	var got Rule
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal returned %v", err)
+	}
+
+	if got.ID == nil || *got.ID != 42 {
+		t.Fatalf("ID = %v, want 42", got.ID)
+	}
+	if got.Attributes["name"] != "Assign Admins" {
+		t.Fatalf("Attributes[name] = %v, want %q", got.Attributes["name"], "Assign Admins")
+	}
+	if _, ok := got.Attributes["id"]; ok {
+		t.Fatal("Attributes should not contain id once it has been pulled out into ID")
+	}
+}
+
+func TestRuleUnmarshalWithoutID(t *testing.T) {
+	var rule Rule
+	if err := json.Unmarshal([]byte(`{"name":"no id yet"}`), &rule); err != nil {
+		t.Fatalf("Unmarshal returned %v", err)
+	}
+	if rule.ID != nil {
+		t.Fatalf("ID = %v, want nil since the payload had none", rule.ID)
+	}
+	if rule.Attributes["name"] != "no id yet" {
+		t.Fatalf("Attributes[name] = %v, want %q", rule.Attributes["name"], "no id yet")
+	}
+}
@@ -1,91 +1,346 @@
 package apps
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
 	"github.com/onelogin/onelogin-go-sdk/internal/customerrors"
-	"github.com/onelogin/onelogin-go-sdk/pkg/oltypes"
 	"github.com/onelogin/onelogin-go-sdk/pkg/services"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/apps/parameters"
+	"github.com/onelogin/onelogin-go-sdk/pkg/services/apps/rules"
 	"github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
-	"log"
 )
 
 const errAppsV2Context = "apps v2 service"
 
+// defaultConcurrency is the fan-out used for per-rule/per-parameter calls when
+// V2ServiceOptions.Concurrency is not set.
+const defaultConcurrency = 4
+
+// V2ServiceOptions configures optional, non-essential behavior of a V2Service.
+type V2ServiceOptions struct {
+	// Concurrency caps how many rule/parameter upserts or deletes are allowed
+	// to be in flight at once. Values <= 0 fall back to defaultConcurrency.
+	Concurrency int
+	// RetryPolicy overrides how per-rule/per-parameter calls are retried.
+	// Nil falls back to defaultRetryPolicy.
+	RetryPolicy *RetryPolicy
+	// Logger overrides how V2Service reports rule/parameter upserts, prune
+	// decisions, and GetOne recovery fallbacks. Nil falls back to a
+	// services.StdLogger.
+	Logger services.Logger
+}
+
 // V2Service holds the information needed to interface with a repository
 type V2Service struct {
 	Endpoint, ErrorContext string
 	Repository             services.Repository
+	Concurrency            int
+	RetryPolicy            RetryPolicy
+	Logger                 services.Logger
+	// Rules and Parameters let callers target an app's rules or parameters
+	// directly. V2Service itself uses them under the hood for Create/Update.
+	Rules      *rules.RulesService
+	Parameters *parameters.ParametersService
 }
 
 // New creates the new svc service v2.
-func New(repo services.Repository, host string) *V2Service {
+func New(repo services.Repository, host string, options ...V2ServiceOptions) *V2Service {
+	concurrency := defaultConcurrency
+	retryPolicy := defaultRetryPolicy
+	var logger services.Logger = services.NewStdLogger()
+	if len(options) > 0 {
+		if options[0].Concurrency > 0 {
+			concurrency = options[0].Concurrency
+		}
+		if options[0].RetryPolicy != nil {
+			retryPolicy = *options[0].RetryPolicy
+		}
+		if options[0].Logger != nil {
+			logger = options[0].Logger
+		}
+	}
 	return &V2Service{
 		Endpoint:     fmt.Sprintf("%s/api/2/apps", host),
 		Repository:   repo,
 		ErrorContext: errAppsV2Context,
+		Concurrency:  concurrency,
+		RetryPolicy:  retryPolicy,
+		Logger:       logger,
+		Rules:        rules.New(repo, host),
+		Parameters:   parameters.New(repo, host),
 	}
 }
 
+// runPooled calls fn once for every i in [0, n), spreading the work across up
+// to svc.Concurrency goroutines, and stacks whatever errors come back. A
+// cancelled ctx stops any work that hasn't started yet.
+func (svc *V2Service) runPooled(ctx context.Context, n int, fn func(ctx context.Context, i int) error) error {
+	if n == 0 {
+		return nil
+	}
+	concurrency := svc.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		errs  []error
+		sem   = make(chan struct{}, concurrency)
+	)
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			errMu.Lock()
+			errs = append(errs, ctx.Err())
+			errMu.Unlock()
+			break dispatch
+		case sem <- struct{}{}:
+		}
+		// select picks at random when both cases are ready, so check ctx again
+		// before starting work that a cancelled ctx must stop.
+		if err := ctx.Err(); err != nil {
+			<-sem
+			errMu.Lock()
+			errs = append(errs, err)
+			errMu.Unlock()
+			break dispatch
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(ctx, i); err != nil {
+				errMu.Lock()
+				errs = append(errs, err)
+				errMu.Unlock()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	return customerrors.StackErrors(errs)
+}
+
 // Query retrieves all the apps from the repository that meet the query criteria passed in the
-// request payload. If an empty payload is given, it will retrieve all apps
+// request payload. If an empty payload is given, it will retrieve all apps. Results are paged
+// through automatically by following the API's pagination envelope; set query.MaxResults to cap
+// the total number of apps returned.
 func (svc *V2Service) Query(query *AppsQuery) ([]App, error) {
-	resp, err := svc.Repository.Read(olhttp.OLHTTPRequest{
-		URL:        svc.Endpoint,
+	return svc.QueryContext(context.Background(), query)
+}
+
+// QueryContext is Query with a caller-supplied context, so deadlines and cancellation
+// propagate into the per-page and per-app rules lookups it makes under the hood.
+func (svc *V2Service) QueryContext(ctx context.Context, query *AppsQuery) ([]App, error) {
+	maxResults := 0
+	if query != nil {
+		maxResults = query.MaxResults
+	}
+
+	var all []App
+
+	url := svc.Endpoint
+	page := query
+	for {
+		apps, nextLink, err := svc.queryPage(ctx, url, page)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, apps...)
+
+		if maxResults > 0 && len(all) >= maxResults {
+			return all[:maxResults], nil
+		}
+		if nextLink == "" {
+			return all, nil
+		}
+		url, page = nextLink, nil
+	}
+}
+
+// QueryStream is QueryContext, but streams apps to appsCh as each page arrives instead of
+// buffering the whole result set. Both channels are closed once the query is exhausted; errCh
+// receives at most one error, which ends the stream.
+func (svc *V2Service) QueryStream(ctx context.Context, query *AppsQuery) (<-chan App, <-chan error) {
+	appsCh := make(chan App)
+	errCh := make(chan error, 1)
+
+	maxResults := 0
+	if query != nil {
+		maxResults = query.MaxResults
+	}
+
+	go func() {
+		defer close(appsCh)
+		defer close(errCh)
+
+		sent := 0
+		url := svc.Endpoint
+		page := query
+		for {
+			apps, nextLink, err := svc.queryPage(ctx, url, page)
+			if err != nil {
+				errCh <- err
+				return
+			}
+
+			for _, app := range apps {
+				if maxResults > 0 && sent >= maxResults {
+					return
+				}
+				select {
+				case appsCh <- app:
+					sent++
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+
+			if nextLink == "" {
+				return
+			}
+			url, page = nextLink, nil
+		}
+	}()
+
+	return appsCh, errCh
+}
+
+// queryPage fetches a single page of apps, along with each app's rules, from url. query is sent
+// as the request payload and should be nil when url is already a pagination link carrying its own
+// criteria. It returns the link to the next page, or "" if this was the last one.
+func (svc *V2Service) queryPage(ctx context.Context, url string, query *AppsQuery) ([]App, string, error) {
+	req := olhttp.OLHTTPRequest{
+		URL:        url,
 		Headers:    map[string]string{"Content-Type": "application/json"},
 		AuthMethod: "bearer",
-		Payload:    query,
-	})
+		Context:    ctx,
+	}
+	if query != nil {
+		req.Payload = query
+	}
+
+	resp, err := svc.Repository.Read(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	var apps []App
-	json.Unmarshal(resp, &apps)
+	apps, nextLink, err := decodeAppsPage(resp.Body, resp.Header)
+	if err != nil {
+		return nil, "", err
+	}
 
 	for i := range apps {
 		resp, err := svc.Repository.Read(olhttp.OLHTTPRequest{
 			URL:        fmt.Sprintf("%s/%d/rules", svc.Endpoint, *apps[i].ID),
 			Headers:    map[string]string{"Content-Type": "application/json"},
 			AuthMethod: "bearer",
+			Context:    ctx,
 		})
 		if err != nil {
-			return apps, err
+			return apps, "", err
 		}
-		var rules []AppRule
-		json.Unmarshal(resp, &rules)
+		var appRules []AppRule
+		json.Unmarshal(resp.Body, &appRules)
 
-		apps[i].Rules = rules
+		apps[i].Rules = appRules
 	}
 
-	return apps, nil
+	return apps, nextLink, nil
+}
+
+// decodeAppsPage decodes a page of apps out of body, which is either a bare JSON array of apps or
+// an envelope of the shape {"data": [...], "pagination": {"next_link": "..."}}. The next page's
+// link is taken from the envelope's pagination object if present, falling back to a GitHub-style
+// Link response header (rel="next") for APIs that paginate via headers instead of an envelope. It
+// returns "" if neither source names a next page.
+func decodeAppsPage(body []byte, header http.Header) ([]App, string, error) {
+	var envelope struct {
+		Data       []App `json:"data"`
+		Pagination *struct {
+			NextLink string `json:"next_link"`
+		} `json:"pagination"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && (envelope.Data != nil || envelope.Pagination != nil) {
+		if envelope.Pagination != nil && envelope.Pagination.NextLink != "" {
+			return envelope.Data, envelope.Pagination.NextLink, nil
+		}
+		return envelope.Data, nextLinkFromHeader(header), nil
+	}
+
+	var apps []App
+	if err := json.Unmarshal(body, &apps); err != nil {
+		return nil, "", err
+	}
+	return apps, nextLinkFromHeader(header), nil
+}
+
+// nextLinkFromHeader extracts the URL marked rel="next" out of a GitHub-style Link header
+// (e.g. `<https://api.example.com/apps?cursor=abc>; rel="next"`), or "" if there isn't one.
+func nextLinkFromHeader(header http.Header) string {
+	for _, part := range strings.Split(header.Get("Link"), ",") {
+		segments := strings.Split(strings.TrimSpace(part), ";")
+		if len(segments) < 2 {
+			continue
+		}
+		url := strings.TrimSpace(segments[0])
+		url = strings.TrimPrefix(url, "<")
+		url = strings.TrimSuffix(url, ">")
+		for _, param := range segments[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return url
+			}
+		}
+	}
+	return ""
 }
 
 // GetOne retrieves the app by id, and if successful, it returns
 // the http response and the pointer to the app.
 func (svc *V2Service) GetOne(id int32) (*App, error) {
+	return svc.GetOneContext(context.Background(), id)
+}
+
+// GetOneContext is GetOne with a caller-supplied context.
+func (svc *V2Service) GetOneContext(ctx context.Context, id int32) (*App, error) {
 	resp, err := svc.Repository.Read(olhttp.OLHTTPRequest{
 		URL:        fmt.Sprintf("%s/%d", svc.Endpoint, id),
 		Headers:    map[string]string{"Content-Type": "application/json"},
 		AuthMethod: "bearer",
+		Context:    ctx,
 	})
 	if err != nil {
 		return nil, err
 	}
 
 	var app App
-	json.Unmarshal(resp, &app)
+	json.Unmarshal(resp.Body, &app)
 	resp, err = svc.Repository.Read(olhttp.OLHTTPRequest{
 		URL:        fmt.Sprintf("%s/%d/rules", svc.Endpoint, *app.ID),
 		Headers:    map[string]string{"Content-Type": "application/json"},
 		AuthMethod: "bearer",
+		Context:    ctx,
 	})
 
 	if err != nil {
 		return &app, err
 	}
 	var rules []AppRule
-	json.Unmarshal(resp, &rules)
+	json.Unmarshal(resp.Body, &rules)
 	app.Rules = rules
 
 	return &app, nil
@@ -94,58 +349,75 @@ func (svc *V2Service) GetOne(id int32) (*App, error) {
 // Create creates a new app, and if successful, it returns
 // the http response and the pointer to the app.
 func (svc *V2Service) Create(app *App) (*App, error) {
+	return svc.CreateContext(context.Background(), app)
+}
+
+// CreateContext is Create with a caller-supplied context, threaded through the app
+// create call as well as the rule upserts and recovery read that follow it.
+func (svc *V2Service) CreateContext(ctx context.Context, app *App) (*App, error) {
 	var newApp App
 	resp, err := svc.Repository.Create(olhttp.OLHTTPRequest{
 		URL:        svc.Endpoint,
 		Headers:    map[string]string{"Content-Type": "application/json"},
 		AuthMethod: "bearer",
 		Payload:    app,
+		Context:    ctx,
 	})
 	if err != nil {
 		return &newApp, err
 	}
-	json.Unmarshal(resp, &newApp)
+	json.Unmarshal(resp.Body, &newApp)
 	newApp.Rules = app.Rules
-	if err = svc.saveAppRules(&newApp); err != nil {
-		recoveredAppState, recoverErr := svc.GetOne(*newApp.ID)
+	if err = svc.saveAppRules(ctx, &newApp); err != nil {
+		svc.Logger.Warn("recovering app state after rule save failure", "operation", "Create", "app_id", *newApp.ID, "error", err)
+		recoveredAppState, recoverErr := svc.GetOneContext(ctx, *newApp.ID)
 		if recoverErr != nil {
 			return nil, err
 		}
 		return recoveredAppState, err
 	}
 
-	return svc.GetOne(*newApp.ID)
+	return svc.GetOneContext(ctx, *newApp.ID)
 }
 
 // Update updates an existing app, and if successful, it returns
 // the http response and the pointer to the updated app.
 func (svc *V2Service) Update(id int32, app *App) (*App, error) {
+	return svc.UpdateContext(context.Background(), id, app)
+}
+
+// UpdateContext is Update with a caller-supplied context, threaded through the app
+// update call, the rule/parameter reconciliation, and the recovery reads that follow it.
+func (svc *V2Service) UpdateContext(ctx context.Context, id int32, app *App) (*App, error) {
 	var updatedApp App
 	resp, err := svc.Repository.Update(olhttp.OLHTTPRequest{
 		URL:        fmt.Sprintf("%s/%d", svc.Endpoint, id),
 		Headers:    map[string]string{"Content-Type": "application/json"},
 		AuthMethod: "bearer",
 		Payload:    app,
+		Context:    ctx,
 	})
 	if err != nil {
 		return &updatedApp, err
 	}
-	json.Unmarshal(resp, &updatedApp)
+	json.Unmarshal(resp.Body, &updatedApp)
 	updatedApp.Rules = app.Rules // attach rules to updated app here since rules are not returned with the app request
 
-	if err = svc.saveAppRules(&updatedApp); err != nil {
-		recoveredAppState, recoverErr := svc.GetOne(*updatedApp.ID)
+	if err = svc.saveAppRules(ctx, &updatedApp); err != nil {
+		svc.Logger.Warn("recovering app state after rule save failure", "operation", "Update", "app_id", *updatedApp.ID, "error", err)
+		recoveredAppState, recoverErr := svc.GetOneContext(ctx, *updatedApp.ID)
 		if recoverErr != nil {
 			return nil, err
 		}
 		return recoveredAppState, err
 	}
 
-	pruneRuleErr := svc.pruneAppRules(&app.Rules, &updatedApp)
-	pruneParamErr := svc.pruneParameters(&app.Parameters, &updatedApp)
+	pruneRuleErr := svc.pruneAppRules(ctx, &app.Rules, &updatedApp)
+	pruneParamErr := svc.pruneParameters(ctx, &app.Parameters, &updatedApp)
 
 	if pruneRuleErr != nil || pruneParamErr != nil {
-		recoveredAppState, recoverErr := svc.GetOne(*updatedApp.ID)
+		svc.Logger.Warn("recovering app state after rule/parameter prune failure", "operation", "Update", "app_id", *updatedApp.ID, "rule_error", pruneRuleErr, "parameter_error", pruneParamErr)
+		recoveredAppState, recoverErr := svc.GetOneContext(ctx, *updatedApp.ID)
 		if recoverErr != nil {
 			return nil, err
 		}
@@ -155,117 +427,124 @@ func (svc *V2Service) Update(id int32, app *App) (*App, error) {
 		return recoveredAppState, pruneParamErr
 	}
 	// re-read the app so we return one with all the parameters changes made via each individual parameters call
-	return svc.GetOne(*updatedApp.ID)
+	return svc.GetOneContext(ctx, *updatedApp.ID)
 }
 
 // Destroy deletes the app for the id, and if successful, it returns nil
 func (svc *V2Service) Destroy(id int32) error {
+	return svc.DestroyContext(context.Background(), id)
+}
+
+// DestroyContext is Destroy with a caller-supplied context.
+func (svc *V2Service) DestroyContext(ctx context.Context, id int32) error {
 	if _, err := svc.Repository.Destroy(olhttp.OLHTTPRequest{
 		URL:        fmt.Sprintf("%s/%d", svc.Endpoint, id),
 		Headers:    map[string]string{"Content-Type": "application/json"},
 		AuthMethod: "bearer",
+		Context:    ctx,
 	}); err != nil {
 		return err
 	}
 	return nil
 }
 
+// ruleToRulesRule converts an AppRule into the rules package's own Rule type
+// by round-tripping it through JSON, so the two packages can share a wire
+// shape without either one importing the other.
+func ruleToRulesRule(rule AppRule) (rules.Rule, error) {
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return rules.Rule{}, err
+	}
+	var converted rules.Rule
+	err = json.Unmarshal(data, &converted)
+	return converted, err
+}
+
 // Given a list of requested rules, go to the API, and pluck (delete) all the rules that are not on the
 // request list. At this point the app holds all existing rules in the API.
 // Rules not on the request list are assumed to be removed by the caller.
-func (svc *V2Service) pruneParameters(requestedParams *map[string]AppParameters, app *App) error {
-	var delErrors []error
+func (svc *V2Service) pruneParameters(ctx context.Context, requestedParams *map[string]AppParameters, app *App) error {
 	keepMap := make(map[int32]bool, len(*requestedParams))
 	for _, param := range *requestedParams {
 		keepMap[*param.ID] = true
 	}
 	// no need to call down app parameters specifically like we do for rules. parameters returned as part of app update
-	for _, delCandidate := range app.Parameters {
-		if !keepMap[*delCandidate.ID] {
-			if _, err := svc.Repository.Destroy(olhttp.OLHTTPRequest{
-				URL:        fmt.Sprintf("%s/%d/parameters/%d", svc.Endpoint, *app.ID, *delCandidate.ID),
-				Headers:    map[string]string{"Content-Type": "application/json"},
-				AuthMethod: "bearer",
-			}); err != nil {
-				delErrors = append(delErrors, err)
-			}
+	var delCandidates []AppParameters
+	for _, candidate := range app.Parameters {
+		if !keepMap[*candidate.ID] {
+			delCandidates = append(delCandidates, candidate)
 		}
 	}
-	return customerrors.StackErrors(delErrors)
+
+	return svc.runPooled(ctx, len(delCandidates), func(ctx context.Context, i int) error {
+		paramID := *delCandidates[i].ID
+		svc.Logger.Info("pruning parameter", "app_id", *app.ID, "parameter_id", paramID)
+		return withRetry(ctx, svc.RetryPolicy, func() error {
+			return svc.Parameters.Delete(ctx, *app.ID, paramID)
+		})
+	})
 }
 
 // Given a list of requested rules, go to the API, and pluck (delete) all the rules that are not on the
 // request list. At this point, the app holds all the existing rules in the API.
 // Rules not on the request list are assumed to be removed by the caller.
-func (svc *V2Service) pruneAppRules(requestedRules *[]AppRule, app *App) error {
-	var (
-		savedRules []AppRule
-		delErrors  []error
-	)
-	resp, _ := svc.Repository.Read(olhttp.OLHTTPRequest{
-		URL:        fmt.Sprintf("%s/%d/rules", svc.Endpoint, *app.ID),
-		Headers:    map[string]string{"Content-Type": "application/json"},
-		AuthMethod: "bearer",
-	})
-
-	json.Unmarshal(resp, &savedRules)
+func (svc *V2Service) pruneAppRules(ctx context.Context, requestedRules *[]AppRule, app *App) error {
+	savedRules, _ := svc.Rules.List(ctx, *app.ID)
 
 	keepMap := make(map[int32]bool, len(*requestedRules))
 	for _, rule := range *requestedRules {
 		keepMap[*rule.ID] = true
 	}
-	for _, delCandidate := range savedRules {
-		if !keepMap[*delCandidate.ID] {
-			if _, err := svc.Repository.Destroy(olhttp.OLHTTPRequest{
-				URL:        fmt.Sprintf("%s/%d/rules/%d", svc.Endpoint, *app.ID, *delCandidate.ID),
-				Headers:    map[string]string{"Content-Type": "application/json"},
-				AuthMethod: "bearer",
-			}); err != nil {
-				delErrors = append(delErrors, err)
-			}
 
+	var delCandidates []rules.Rule
+	for _, candidate := range savedRules {
+		if !keepMap[*candidate.ID] {
+			delCandidates = append(delCandidates, candidate)
 		}
 	}
-	return customerrors.StackErrors(delErrors)
+
+	return svc.runPooled(ctx, len(delCandidates), func(ctx context.Context, i int) error {
+		ruleID := *delCandidates[i].ID
+		svc.Logger.Info("pruning rule", "app_id", *app.ID, "rule_id", ruleID)
+		return withRetry(ctx, svc.RetryPolicy, func() error {
+			return svc.Rules.Delete(ctx, *app.ID, ruleID)
+		})
+	})
 }
 
 // create or update (upsert if you will) the rules tied to this app. If an upsert fails, the rest will continue, then the saved
 // rules will be tied to the app an error will be returned for the caller to decide what to do
-func (svc *V2Service) saveAppRules(app *App) error {
-	var (
-		err         error
-		resp        []byte
-		writeErrors []error
-	)
-	for i := range (*app).Rules {
-		if app.Rules[i].ID != nil {
-			resp, err = svc.Repository.Update(olhttp.OLHTTPRequest{
-				URL:        fmt.Sprintf("%s/%d/rules/%d", svc.Endpoint, *app.ID, *app.Rules[i].ID),
-				Headers:    map[string]string{"Content-Type": "application/json"},
-				AuthMethod: "bearer",
-				Payload:    app.Rules[i],
-			})
-			if err != nil {
-				log.Println("Partial Rules State:", err)
-				writeErrors = append(writeErrors, err)
-			}
-		} else {
-			resp, err = svc.Repository.Create(olhttp.OLHTTPRequest{
-				URL:        fmt.Sprintf("%s/%d/rules", svc.Endpoint, *app.ID),
-				Headers:    map[string]string{"Content-Type": "application/json"},
-				AuthMethod: "bearer",
-				Payload:    app.Rules[i],
-			})
-			if err != nil {
-				log.Println("Partial Rules State:", err)
-				writeErrors = append(writeErrors, err)
-			}
+func (svc *V2Service) saveAppRules(ctx context.Context, app *App) error {
+	var idMu sync.Mutex
+
+	return svc.runPooled(ctx, len(app.Rules), func(ctx context.Context, i int) error {
+		rule, err := ruleToRulesRule(app.Rules[i])
+		if err != nil {
+			return err
 		}
-		if err == nil {
-			var ruleID map[string]int
-			json.Unmarshal(resp, &ruleID)
-			app.Rules[i].ID = oltypes.Int32(int32(ruleID["id"]))
+
+		var upserted rules.Rule
+		err = withRetry(ctx, svc.RetryPolicy, func() error {
+			var callErr error
+			upserted, callErr = svc.Rules.Upsert(ctx, *app.ID, rule)
+			return callErr
+		})
+		if err != nil {
+			ruleID := int32(0)
+			if rule.ID != nil {
+				ruleID = *rule.ID
+			}
+			svc.Logger.Warn("Partial Rules State", "app_id", *app.ID, "rule_id", ruleID, "error", err)
+			return err
 		}
-	}
-	return customerrors.StackErrors(writeErrors)
+		svc.Logger.Info("upserted rule", "app_id", *app.ID, "rule_id", *upserted.ID)
+
+		// app.Rules is shared across the worker pool, so rebinding an ID is
+		// guarded even though each goroutine only ever touches its own index.
+		idMu.Lock()
+		app.Rules[i].ID = upserted.ID
+		idMu.Unlock()
+		return nil
+	})
 }
@@ -0,0 +1,49 @@
+package services
+
+import "log"
+
+// Logger is a minimal structured logging interface that services can accept
+// an implementation of, so production callers can plug in JSON output, level
+// filtering, or correlation IDs instead of being stuck with the stdlib
+// logger. Each method takes alternating key/value pairs describing the event.
+type Logger interface {
+	Debug(msg string, keyvals ...interface{})
+	Info(msg string, keyvals ...interface{})
+	Warn(msg string, keyvals ...interface{})
+	Error(msg string, keyvals ...interface{})
+}
+
+// StdLogger adapts the stdlib log package to the Logger interface, and is
+// used by services that aren't given a Logger of their own.
+type StdLogger struct {
+	*log.Logger
+}
+
+// NewStdLogger creates a StdLogger that writes through the stdlib's default logger.
+func NewStdLogger() *StdLogger {
+	return &StdLogger{Logger: log.Default()}
+}
+
+// Debug logs msg and keyvals at debug level.
+func (l *StdLogger) Debug(msg string, keyvals ...interface{}) {
+	l.log("DEBUG", msg, keyvals)
+}
+
+// Info logs msg and keyvals at info level.
+func (l *StdLogger) Info(msg string, keyvals ...interface{}) {
+	l.log("INFO", msg, keyvals)
+}
+
+// Warn logs msg and keyvals at warn level.
+func (l *StdLogger) Warn(msg string, keyvals ...interface{}) {
+	l.log("WARN", msg, keyvals)
+}
+
+// Error logs msg and keyvals at error level.
+func (l *StdLogger) Error(msg string, keyvals ...interface{}) {
+	l.log("ERROR", msg, keyvals)
+}
+
+func (l *StdLogger) log(level, msg string, keyvals []interface{}) {
+	l.Println(append([]interface{}{level, msg}, keyvals...)...)
+}
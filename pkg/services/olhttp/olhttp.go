@@ -0,0 +1,161 @@
+// Package olhttp is the default services.Repository implementation, issuing
+// requests against the OneLogin API over HTTP.
+package olhttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// OLHTTPRequest describes a single call to the OneLogin API.
+type OLHTTPRequest struct {
+	URL        string
+	Headers    map[string]string
+	AuthMethod string
+	Payload    interface{}
+	// Context is attached to the outgoing request via http.NewRequestWithContext,
+	// so a cancelled or deadline-exceeded ctx aborts the call in flight instead
+	// of just failing to be noticed by the caller. A nil Context behaves like
+	// context.Background().
+	Context context.Context
+}
+
+func (r OLHTTPRequest) context() context.Context {
+	if r.Context != nil {
+		return r.Context
+	}
+	return context.Background()
+}
+
+// Response is the result of a single Repository call: the raw response body
+// plus its headers, so callers that need pagination/rate-limit metadata
+// (e.g. apps.V2Service's Query) aren't limited to what's in the JSON body.
+type Response struct {
+	Body   []byte
+	Header http.Header
+}
+
+// Client issues OLHTTPRequests over HTTP and implements services.Repository.
+type Client struct {
+	HTTPClient  *http.Client
+	BearerToken string
+}
+
+// NewClient creates a Client that authenticates with bearerToken and sends
+// requests through http.DefaultClient.
+func NewClient(bearerToken string) *Client {
+	return &Client{HTTPClient: http.DefaultClient, BearerToken: bearerToken}
+}
+
+// Read issues a GET request.
+func (c *Client) Read(req OLHTTPRequest) (*Response, error) {
+	return c.do(http.MethodGet, req)
+}
+
+// Create issues a POST request.
+func (c *Client) Create(req OLHTTPRequest) (*Response, error) {
+	return c.do(http.MethodPost, req)
+}
+
+// Update issues a PUT request.
+func (c *Client) Update(req OLHTTPRequest) (*Response, error) {
+	return c.do(http.MethodPut, req)
+}
+
+// Destroy issues a DELETE request.
+func (c *Client) Destroy(req OLHTTPRequest) (*Response, error) {
+	return c.do(http.MethodDelete, req)
+}
+
+func (c *Client) do(method string, req OLHTTPRequest) (*Response, error) {
+	var body io.Reader
+	if req.Payload != nil {
+		data, err := json.Marshal(req.Payload)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+
+	httpReq, err := http.NewRequestWithContext(req.context(), method, req.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range req.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	if req.AuthMethod == "bearer" && c.BearerToken != "" {
+		httpReq.Header.Set("Authorization", "bearer "+c.BearerToken)
+	}
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Response{Body: respBody, Header: resp.Header}
+	if resp.StatusCode >= 400 {
+		return out, newHTTPError(resp, respBody)
+	}
+
+	return out, nil
+}
+
+// HTTPError is returned when the OneLogin API responds with a status of 400
+// or above. It carries enough of the response for a caller like
+// apps.RetryPolicy to decide whether the request is worth retrying.
+type HTTPError struct {
+	status     string
+	statusCode int
+	retryAfter time.Duration
+	Body       []byte
+}
+
+func newHTTPError(resp *http.Response, body []byte) *HTTPError {
+	return &HTTPError{
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		Body:       body,
+	}
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("onelogin api error: %s", e.status)
+}
+
+// StatusCode returns the HTTP status code of the failed response.
+func (e *HTTPError) StatusCode() int {
+	return e.statusCode
+}
+
+// RetryAfter returns the delay the API asked for via the Retry-After header,
+// or 0 if it didn't send one.
+func (e *HTTPError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(v); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
@@ -0,0 +1,13 @@
+package services
+
+import "github.com/onelogin/onelogin-go-sdk/pkg/services/olhttp"
+
+// Repository is the interface V2Service and its sibling services use to
+// execute HTTP calls against the OneLogin API, so they can be tested or
+// pointed at a different transport without depending on a concrete client.
+type Repository interface {
+	Read(olhttp.OLHTTPRequest) (*olhttp.Response, error)
+	Create(olhttp.OLHTTPRequest) (*olhttp.Response, error)
+	Update(olhttp.OLHTTPRequest) (*olhttp.Response, error)
+	Destroy(olhttp.OLHTTPRequest) (*olhttp.Response, error)
+}